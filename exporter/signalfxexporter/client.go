@@ -0,0 +1,371 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/translation"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+const (
+	// datapointIngestPath and eventIngestPath are appended to the
+	// configured ingest URL so the datapoint and event clients post to
+	// their own endpoints rather than sharing one.
+	datapointIngestPath = "/v2/datapoint"
+	eventIngestPath     = "/v2/event"
+)
+
+// withIngestPath returns a copy of base with suffix appended to its path,
+// used to derive the datapoint and event endpoints from a single
+// configured (or realm-derived) ingest URL.
+func withIngestPath(base *url.URL, suffix string) *url.URL {
+	u := *base
+	u.Path = strings.TrimSuffix(u.Path, "/") + suffix
+	return &u
+}
+
+// sfxClientBase holds the state shared between the datapoint and event
+// clients: where to send payloads, what headers to send with every
+// request, the underlying http.Client, and the compressor used to encode
+// the request body.
+type sfxClientBase struct {
+	ingestURL *url.URL
+	headers   map[string]string
+	client    *http.Client
+
+	compressor Compressor
+	// compressionMinSizeBytes is the payload size below which requests are
+	// sent uncompressed regardless of compressor, since compression
+	// overhead dominates on small bodies.
+	compressionMinSizeBytes int
+
+	telemetry *telemetry
+}
+
+// writeRequest wraps body with the client's compressor (falling back to an
+// uncompressed request when the payload is smaller than
+// compressionMinSizeBytes), issues the POST against endpoint (used only to
+// label the request duration metric), and records the resulting duration
+// and compression ratio. headerOverride, if non-nil, is applied on top of
+// the client's configured headers, letting access_token_passthrough swap
+// in a per-resource access token for this request only.
+func (c *sfxClientBase) writeRequest(ctx context.Context, body []byte, endpoint string, headerOverride map[string]string) (*http.Response, error) {
+	compressor := c.compressor
+	if len(body) < c.compressionMinSizeBytes {
+		compressor = identityCompressor{}
+	}
+
+	var buf bytes.Buffer
+	wc, err := compressor.Wrap(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare %s compressor: %w", compressor.Name(), err)
+	}
+	if _, err := wc.Write(body); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if err := wc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to flush compressed payload: %w", err)
+	}
+
+	if c.telemetry != nil && buf.Len() > 0 {
+		c.telemetry.compressionRatio.Record(ctx, float64(len(body))/float64(buf.Len()))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.ingestURL.String(), &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range headerOverride {
+		req.Header.Set(k, v)
+	}
+	if encoding := compressor.Name(); encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if c.telemetry != nil {
+		c.telemetry.httpRequestDuration.Record(ctx, time.Since(start).Seconds(),
+			metric.WithAttributes(attribute.String("endpoint", endpoint)))
+	}
+	return resp, err
+}
+
+type sfxDPClient struct {
+	sfxClientBase
+	logDataPoints          bool
+	logger                 *zap.Logger
+	rejectionLogger        *zap.Logger
+	accessTokenPassthrough bool
+	converter              *translation.MetricsConverter
+}
+
+func (c *sfxDPClient) pushMetricsData(ctx context.Context, md pmetric.Metrics) (int, error) {
+	dps := c.converter.MetricsToSignalFxV2(md)
+	if c.logDataPoints {
+		c.logger.Debug("Sending datapoints", zap.Int("count", len(dps)))
+	}
+
+	if !c.accessTokenPassthrough {
+		return c.sendDatapoints(ctx, dps, nil)
+	}
+
+	// With passthrough enabled, datapoints carrying a per-resource access
+	// token must not be sent under the client's default token, so split
+	// the batch by token and send each under its own header.
+	var dropped int
+	var errs error
+	for token, batch := range groupDatapointsByToken(dps) {
+		var headerOverride map[string]string
+		if token != "" {
+			headerOverride = map[string]string{splunk.SFxAccessTokenHeader: token}
+		}
+		n, err := c.sendDatapoints(ctx, batch, headerOverride)
+		dropped += n
+		errs = errors.Join(errs, err)
+	}
+	return dropped, errs
+}
+
+// sendDatapoints encodes and sends a single batch of already-translated
+// datapoints, applying headerOverride (if any) on top of the client's
+// default headers, and reports how many were dropped.
+func (c *sfxDPClient) sendDatapoints(ctx context.Context, dps []*sfxpb.DataPoint, headerOverride map[string]string) (int, error) {
+	body, err := encodeDatapoints(dps)
+	if err != nil {
+		return len(dps), fmt.Errorf("failed to encode datapoints: %w", err)
+	}
+
+	resp, err := c.writeRequest(ctx, body, "datapoints", headerOverride)
+	if err != nil {
+		return len(dps), err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		c.telemetry.recordDropped(ctx, int64(len(dps)), "http_error")
+		return len(dps), fmt.Errorf("datapoint POST failed with status %d", resp.StatusCode)
+	}
+
+	rejected, err := parseIngestResponse(resp)
+	if err != nil {
+		// The request itself succeeded; a body we can't read for partial
+		// success is not reason enough to retry the whole batch.
+		c.rejectionLogger.Warn("failed to read SignalFx ingest response", zap.Error(err))
+		c.recordSent(ctx, len(dps))
+		return 0, nil
+	}
+	if len(rejected) == 0 {
+		c.recordSent(ctx, len(dps))
+		return 0, nil
+	}
+
+	rejectedDPs := make([]*sfxpb.DataPoint, 0, len(rejected))
+	for _, r := range rejected {
+		if r.Index >= 0 && r.Index < len(dps) {
+			rejectedDPs = append(rejectedDPs, dps[r.Index])
+		}
+	}
+
+	c.rejectionLogger.Warn("SignalFx ingest rejected datapoints",
+		zap.Int("rejected", len(rejectedDPs)),
+		zap.Int("accepted", len(dps)-len(rejectedDPs)))
+	c.recordSent(ctx, len(dps)-len(rejectedDPs))
+	c.telemetry.recordDropped(ctx, int64(len(rejectedDPs)), "rejected_by_ingest")
+
+	// Permanent: dps is already the translated SignalFx-protobuf form, not
+	// the original pmetric.Metrics, so there is no cheap way to hand the
+	// retry queue only the rejected subset. The API already told us why
+	// these are invalid, and retrying the whole batch would just re-send
+	// the datapoints it already accepted, so drop instead.
+	return len(rejectedDPs), consumererror.NewPermanent(summarizeRejections(rejected))
+}
+
+// groupDatapointsByToken partitions dps by the value of their
+// splunk.SFxAccessTokenLabel dimension (stripping it in the process),
+// grouping datapoints with no such dimension under the empty string so
+// they are sent with the client's default token.
+func groupDatapointsByToken(dps []*sfxpb.DataPoint) map[string][]*sfxpb.DataPoint {
+	groups := make(map[string][]*sfxpb.DataPoint)
+	for _, dp := range dps {
+		token, rest := extractAccessToken(dp.Dimensions)
+		dp.Dimensions = rest
+		groups[token] = append(groups[token], dp)
+	}
+	return groups
+}
+
+// extractAccessToken returns the value of the splunk.SFxAccessTokenLabel
+// dimension, if present, along with dims with that dimension removed.
+func extractAccessToken(dims []*sfxpb.Dimension) (token string, rest []*sfxpb.Dimension) {
+	for i, d := range dims {
+		if d.Key == splunk.SFxAccessTokenLabel {
+			rest = make([]*sfxpb.Dimension, 0, len(dims)-1)
+			rest = append(rest, dims[:i]...)
+			rest = append(rest, dims[i+1:]...)
+			return d.Value, rest
+		}
+	}
+	return "", dims
+}
+
+func (c *sfxDPClient) recordSent(ctx context.Context, count int) {
+	if c.telemetry == nil || count == 0 {
+		return
+	}
+	c.telemetry.datapointsSent.Add(ctx, int64(count))
+}
+
+func encodeDatapoints(dps []*sfxpb.DataPoint) ([]byte, error) {
+	msg := sfxpb.DataPointUploadMessage{Datapoints: dps}
+	return msg.Marshal()
+}
+
+type sfxEventClient struct {
+	sfxClientBase
+	logger                 *zap.Logger
+	rejectionLogger        *zap.Logger
+	accessTokenPassthrough bool
+}
+
+func (c *sfxEventClient) pushLogsData(ctx context.Context, ld plog.Logs) (int, error) {
+	events, dropped := logsToSignalFxV2(ld)
+
+	if !c.accessTokenPassthrough {
+		n, err := c.sendEvents(ctx, events, nil)
+		return dropped + n, err
+	}
+
+	// See the comment in sfxDPClient.pushMetricsData: passthrough means
+	// events for different resources may carry different access tokens,
+	// so they can't all go out under the client's default token.
+	var errs error
+	for token, batch := range groupEventsByToken(events) {
+		var headerOverride map[string]string
+		if token != "" {
+			headerOverride = map[string]string{splunk.SFxAccessTokenHeader: token}
+		}
+		n, err := c.sendEvents(ctx, batch, headerOverride)
+		dropped += n
+		errs = errors.Join(errs, err)
+	}
+	return dropped, errs
+}
+
+// sendEvents encodes and sends a single batch of already-translated
+// events, applying headerOverride (if any) on top of the client's default
+// headers, and reports how many were dropped.
+func (c *sfxEventClient) sendEvents(ctx context.Context, events []*sfxpb.Event, headerOverride map[string]string) (int, error) {
+	body, err := encodeEvents(events)
+	if err != nil {
+		return len(events), fmt.Errorf("failed to encode events: %w", err)
+	}
+
+	resp, err := c.writeRequest(ctx, body, "events", headerOverride)
+	if err != nil {
+		return len(events), err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return len(events), fmt.Errorf("event POST failed with status %d", resp.StatusCode)
+	}
+
+	rejected, err := parseIngestResponse(resp)
+	if err != nil {
+		c.rejectionLogger.Warn("failed to read SignalFx ingest response", zap.Error(err))
+		c.recordSent(ctx, len(events))
+		return 0, nil
+	}
+	if len(rejected) == 0 {
+		c.recordSent(ctx, len(events))
+		return 0, nil
+	}
+
+	rejectedEvents := make([]*sfxpb.Event, 0, len(rejected))
+	for _, r := range rejected {
+		if r.Index >= 0 && r.Index < len(events) {
+			rejectedEvents = append(rejectedEvents, events[r.Index])
+		}
+	}
+
+	c.rejectionLogger.Warn("SignalFx ingest rejected events",
+		zap.Int("rejected", len(rejectedEvents)),
+		zap.Int("accepted", len(events)-len(rejectedEvents)))
+	c.recordSent(ctx, len(events)-len(rejectedEvents))
+
+	// Permanent, and with no carried subset: events is already the
+	// translated SignalFx-protobuf form, so see the comment in
+	// sfxDPClient.sendDatapoints for why we can't hand the queue just the
+	// rejected events.
+	return len(rejectedEvents), consumererror.NewPermanent(summarizeRejections(rejected))
+}
+
+// groupEventsByToken partitions events by the value of their
+// splunk.SFxAccessTokenLabel dimension (stripping it in the process),
+// grouping events with no such dimension under the empty string so they
+// are sent with the client's default token.
+func groupEventsByToken(events []*sfxpb.Event) map[string][]*sfxpb.Event {
+	groups := make(map[string][]*sfxpb.Event)
+	for _, ev := range events {
+		token, rest := extractAccessToken(ev.Dimensions)
+		ev.Dimensions = rest
+		groups[token] = append(groups[token], ev)
+	}
+	return groups
+}
+
+func (c *sfxEventClient) recordSent(ctx context.Context, count int) {
+	if c.telemetry == nil || count == 0 {
+		return
+	}
+	c.telemetry.eventsSent.Add(ctx, int64(count))
+}
+
+func encodeEvents(events []*sfxpb.Event) ([]byte, error) {
+	msg := sfxpb.EventUploadMessage{Events: events}
+	return msg.Marshal()
+}
+
+func logsToSignalFxV2(ld plog.Logs) ([]*sfxpb.Event, int) {
+	// Translation of log records to SignalFx events is implemented in
+	// internal/translation; kept out of this package to mirror the
+	// datapoint conversion path.
+	return translation.LogsToSignalFxV2(ld)
+}
@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter"
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	compressionTypeGzip = "gzip"
+	compressionTypeZstd = "zstd"
+	compressionTypeNone = "none"
+
+	// defaultCompressionMinSizeBytes is the payload size below which a
+	// request is sent uncompressed regardless of the configured
+	// compressor: on small metric/event batches the framing overhead of
+	// gzip or zstd outweighs any savings.
+	defaultCompressionMinSizeBytes = 1500
+)
+
+// Compressor encodes an outgoing request body for transport to the
+// SignalFx ingest API.
+type Compressor interface {
+	// Name returns the Content-Encoding header value to advertise for
+	// payloads this Compressor wraps, or "" for the identity compressor.
+	Name() string
+	// Wrap returns a WriteCloser that compresses writes into w. Close must
+	// be called to flush any buffered output before w is read.
+	Wrap(w io.Writer) (io.WriteCloser, error)
+}
+
+// newCompressor returns the Compressor for the given algorithm name
+// ("gzip", "zstd", "none", or "" which defaults to gzip for backwards
+// compatibility).
+func newCompressor(name string) (Compressor, error) {
+	switch name {
+	case "", compressionTypeGzip:
+		return newGzipCompressor(), nil
+	case compressionTypeZstd:
+		return newZstdCompressor()
+	case compressionTypeNone:
+		return identityCompressor{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression type %q", name)
+	}
+}
+
+// pooledWriteCloser returns an underlying compressor to its sync.Pool once
+// the caller is done with it, after flushing via Close.
+type pooledWriteCloser struct {
+	io.WriteCloser
+	release func()
+}
+
+func (p *pooledWriteCloser) Close() error {
+	err := p.WriteCloser.Close()
+	p.release()
+	return err
+}
+
+type gzipCompressor struct {
+	pool sync.Pool
+}
+
+func newGzipCompressor() *gzipCompressor {
+	return &gzipCompressor{
+		pool: sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }},
+	}
+}
+
+func (c *gzipCompressor) Name() string { return compressionTypeGzip }
+
+func (c *gzipCompressor) Wrap(w io.Writer) (io.WriteCloser, error) {
+	gz := c.pool.Get().(*gzip.Writer)
+	gz.Reset(w)
+	return &pooledWriteCloser{WriteCloser: gz, release: func() { c.pool.Put(gz) }}, nil
+}
+
+type zstdCompressor struct {
+	pool sync.Pool
+}
+
+func newZstdCompressor() (*zstdCompressor, error) {
+	// Validate that an encoder can be constructed before handing back a
+	// Compressor whose Wrap would otherwise fail lazily on first use.
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd compressor: %w", err)
+	}
+	enc.Close()
+
+	return &zstdCompressor{
+		pool: sync.Pool{New: func() interface{} {
+			e, _ := zstd.NewWriter(nil)
+			return e
+		}},
+	}, nil
+}
+
+func (c *zstdCompressor) Name() string { return compressionTypeZstd }
+
+func (c *zstdCompressor) Wrap(w io.Writer) (io.WriteCloser, error) {
+	enc := c.pool.Get().(*zstd.Encoder)
+	enc.Reset(w)
+	return &pooledWriteCloser{WriteCloser: enc, release: func() { c.pool.Put(enc) }}, nil
+}
+
+// identityCompressor sends the payload as-is, used for small payloads and
+// for compression: none.
+type identityCompressor struct{}
+
+func (identityCompressor) Name() string { return "" }
+
+func (identityCompressor) Wrap(w io.Writer) (io.WriteCloser, error) {
+	return nopWriteCloser{Writer: w}, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
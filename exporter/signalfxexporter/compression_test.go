@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCompressor(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "", wantName: compressionTypeGzip},
+		{name: compressionTypeGzip, wantName: compressionTypeGzip},
+		{name: compressionTypeZstd, wantName: compressionTypeZstd},
+		{name: compressionTypeNone, wantName: ""},
+		{name: "brotli", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("name="+tt.name, func(t *testing.T) {
+			c, err := newCompressor(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, c)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, c.Name())
+		})
+	}
+}
+
+func TestGzipCompressorRoundTrip(t *testing.T) {
+	c := newGzipCompressor()
+
+	var buf bytes.Buffer
+	wc, err := c.Wrap(&buf)
+	require.NoError(t, err)
+	_, err = wc.Write([]byte("hello world"))
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+
+	gr, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	defer gr.Close()
+	got, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(got))
+}
+
+func TestZstdCompressorRoundTrip(t *testing.T) {
+	c, err := newZstdCompressor()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	wc, err := c.Wrap(&buf)
+	require.NoError(t, err)
+	_, err = wc.Write([]byte("hello world"))
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+
+	dec, err := zstd.NewReader(&buf)
+	require.NoError(t, err)
+	defer dec.Close()
+	got, err := io.ReadAll(dec)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(got))
+}
+
+func TestIdentityCompressorPassesThroughUnmodified(t *testing.T) {
+	c := identityCompressor{}
+	assert.Equal(t, "", c.Name())
+
+	var buf bytes.Buffer
+	wc, err := c.Wrap(&buf)
+	require.NoError(t, err)
+	_, err = wc.Write([]byte("hello world"))
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+
+	assert.Equal(t, "hello world", buf.String())
+}
@@ -0,0 +1,301 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter"
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configtls"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/dpfilters"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/envconfig"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/translation"
+)
+
+// Config defines configuration for the SignalFx exporter.
+type Config struct {
+	confighttp.HTTPClientSettings `mapstructure:",squash"`
+
+	AccessToken string `mapstructure:"access_token"`
+
+	Realm string `mapstructure:"realm"`
+
+	// IngestURL and APIURL, when unset, fall back to SIGNALFX_INGEST_ENDPOINT
+	// and SIGNALFX_API_ENDPOINT (or their per-signal SIGNALFX_METRICS_*,
+	// SIGNALFX_LOGS_*, SIGNALFX_DIMENSIONS_* overrides), and finally to the
+	// realm-derived defaults.
+	IngestURL string `mapstructure:"ingest_url"`
+	APIURL    string `mapstructure:"api_url"`
+
+	IngestTLSSettings configtls.TLSClientSetting `mapstructure:"-"`
+	APITLSSettings    configtls.TLSClientSetting `mapstructure:"-"`
+
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Compression algorithm to use when sending payloads to the SignalFx
+	// ingest API. One of "gzip" (the default), "zstd", or "none".
+	Compression string `mapstructure:"compression"`
+
+	AccessTokenPassthrough bool `mapstructure:"access_token_passthrough"`
+
+	LogDataPoints bool `mapstructure:"log_data_points"`
+
+	LogDimensionUpdates bool `mapstructure:"log_dimension_updates"`
+
+	DeltaTranslationTTL int64 `mapstructure:"delta_translation_ttl"`
+
+	TranslationRules []translation.Rule `mapstructure:"translation_rules"`
+
+	ExcludeMetrics []dpfilters.MetricFilter `mapstructure:"exclude_metrics"`
+	IncludeMetrics []dpfilters.MetricFilter `mapstructure:"include_metrics"`
+
+	SyncHostMetadata bool `mapstructure:"sync_host_metadata"`
+
+	NonAlphanumericDimensionChars string `mapstructure:"nonalphanumeric_dimension_chars"`
+
+	MaxConnections int `mapstructure:"max_connections"`
+
+	// DimensionClient configures the retry/backoff behavior of the
+	// dimension property update client.
+	DimensionClient DimensionClientConfig `mapstructure:"dimension_client"`
+}
+
+// DimensionClientConfig configures how the exporter buffers and retries
+// dimension property updates against the SignalFx API.
+type DimensionClientConfig struct {
+	// SendDelay is how often buffered property updates are flushed. Zero
+	// means "use the default"; there is no meaningful zero value for this
+	// field.
+	SendDelay time.Duration `mapstructure:"send_delay"`
+	// MaxBuffered is the maximum number of property updates buffered
+	// before new updates are dropped. Large deployments with rapid
+	// Kubernetes churn can exhaust the default quickly. Unlike SendDelay,
+	// 0 is a valid, meaningful setting (drop every update instead of
+	// buffering), so this is a pointer to distinguish "unset" from
+	// "explicitly disabled"; leave it nil to use the default.
+	MaxBuffered *int `mapstructure:"max_buffered"`
+	// MaxRetries is the maximum number of attempts for a single property
+	// update before it is dropped. 0 is a valid, meaningful setting
+	// (disable retries entirely), so this is a pointer for the same
+	// reason as MaxBuffered; leave it nil to use the default.
+	MaxRetries *int `mapstructure:"max_retries"`
+	// InitialBackoff is the backoff before the first retry of a failed
+	// update. Zero means "use the default".
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	// MaxBackoff caps the backoff between retries. Zero means "use the
+	// default".
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+	// BackoffMultiplier scales the backoff after each failed attempt.
+	// Zero means "use the default".
+	BackoffMultiplier float64 `mapstructure:"backoff_multiplier"`
+}
+
+const (
+	defaultDimensionSendDelay         = 10 * time.Second
+	defaultDimensionMaxBuffered       = 10000
+	defaultDimensionMaxRetries        = 5
+	defaultDimensionInitialBackoff    = 1 * time.Second
+	defaultDimensionMaxBackoff        = 30 * time.Second
+	defaultDimensionBackoffMultiplier = 2.0
+)
+
+// resolvedDimensionClientConfig is DimensionClientConfig with every field
+// resolved to a concrete value, ready to hand to
+// dimensions.DimensionClientOptions.
+type resolvedDimensionClientConfig struct {
+	SendDelay         time.Duration
+	MaxBuffered       int
+	MaxRetries        int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+}
+
+// withDefaults resolves cfg against the package defaults. Time/multiplier
+// fields treat their zero value as "unset"; MaxBuffered and MaxRetries use
+// a nil pointer for "unset" instead, since 0 is itself a meaningful
+// setting for both.
+func (cfg DimensionClientConfig) withDefaults() resolvedDimensionClientConfig {
+	resolved := resolvedDimensionClientConfig{
+		SendDelay:         cfg.SendDelay,
+		MaxBuffered:       defaultDimensionMaxBuffered,
+		MaxRetries:        defaultDimensionMaxRetries,
+		InitialBackoff:    cfg.InitialBackoff,
+		MaxBackoff:        cfg.MaxBackoff,
+		BackoffMultiplier: cfg.BackoffMultiplier,
+	}
+	if resolved.SendDelay == 0 {
+		resolved.SendDelay = defaultDimensionSendDelay
+	}
+	if cfg.MaxBuffered != nil {
+		resolved.MaxBuffered = *cfg.MaxBuffered
+	}
+	if cfg.MaxRetries != nil {
+		resolved.MaxRetries = *cfg.MaxRetries
+	}
+	if resolved.InitialBackoff == 0 {
+		resolved.InitialBackoff = defaultDimensionInitialBackoff
+	}
+	if resolved.MaxBackoff == 0 {
+		resolved.MaxBackoff = defaultDimensionMaxBackoff
+	}
+	if resolved.BackoffMultiplier == 0 {
+		resolved.BackoffMultiplier = defaultDimensionBackoffMultiplier
+	}
+	return resolved
+}
+
+// resolvedURL reads fileValue, falling back to the environment variable
+// identified by suffix (honoring the per-signal prefix), and parses the
+// result as a URL. It returns nil, nil if neither is set, leaving the
+// realm-derived default (see realmURL) to the caller.
+func resolvedURL(fileValue string, signalPrefix, suffix string) (*url.URL, error) {
+	v := fileValue
+	if v == "" {
+		if envValue, ok := envconfig.Lookup(signalPrefix, suffix); ok {
+			v = envValue
+		}
+	}
+	if v == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", v, err)
+	}
+	return u, nil
+}
+
+// realmURL builds the default endpoint for realm, e.g.
+// https://ingest.us0.signalfx.com for component "ingest". This is the
+// fallback used when neither an explicit URL nor the matching environment
+// variable is set.
+func realmURL(realm, component string) (*url.URL, error) {
+	raw := fmt.Sprintf("https://%s.%s.signalfx.com", component, realm)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid realm %q: %w", realm, err)
+	}
+	return u, nil
+}
+
+// getOptionsFromConfig resolves the options needed to construct the client
+// for the given signal. signalPrefix should be one of
+// envconfig.MetricsPrefix, envconfig.LogsPrefix, or
+// envconfig.DimensionsPrefix, and determines which per-signal environment
+// variables take precedence over the unprefixed ones. Values set directly
+// in the config file always take precedence over the environment.
+func (cfg *Config) getOptionsFromConfig(signalPrefix string) (*exporterOptions, error) {
+	ingestURL, err := resolvedURL(cfg.IngestURL, signalPrefix, envconfig.IngestEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if ingestURL == nil {
+		if cfg.Realm == "" {
+			return nil, fmt.Errorf("one of %q, %s, or %q must be set", "ingest_url", "SIGNALFX_"+envconfig.IngestEndpoint, "realm")
+		}
+		if ingestURL, err = realmURL(cfg.Realm, "ingest"); err != nil {
+			return nil, err
+		}
+	}
+
+	apiURL, err := resolvedURL(cfg.APIURL, signalPrefix, envconfig.APIEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if apiURL == nil {
+		if cfg.Realm == "" {
+			return nil, fmt.Errorf("one of %q, %s, or %q must be set", "api_url", "SIGNALFX_"+envconfig.APIEndpoint, "realm")
+		}
+		if apiURL, err = realmURL(cfg.Realm, "api"); err != nil {
+			return nil, err
+		}
+	}
+
+	token := cfg.AccessToken
+	if token == "" {
+		if v, ok := envconfig.Lookup(signalPrefix, envconfig.AccessToken); ok {
+			token = v
+		}
+	}
+	if token == "" {
+		return nil, fmt.Errorf("%q or %s must be set", "access_token", "SIGNALFX_"+envconfig.AccessToken)
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		if d, ok, derr := envconfig.LookupDuration(signalPrefix, envconfig.Timeout); derr != nil {
+			return nil, fmt.Errorf("invalid %s: %w", "SIGNALFX_"+envconfig.Timeout, derr)
+		} else if ok {
+			timeout = d
+		}
+	}
+
+	compression := cfg.Compression
+	if compression == "" {
+		if v, ok := envconfig.Lookup(signalPrefix, envconfig.Compression); ok {
+			compression = v
+		}
+	}
+	if compression == "" {
+		compression = compressionTypeGzip
+	}
+
+	ingestTLS := cfg.IngestTLSSettings
+	if ingestTLS.CAFile == "" {
+		if v, ok := envconfig.Lookup(signalPrefix, envconfig.Certificate); ok {
+			ingestTLS.CAFile = v
+		}
+	}
+	if ingestTLS.CertFile == "" {
+		if v, ok := envconfig.Lookup(signalPrefix, envconfig.ClientCertificate); ok {
+			ingestTLS.CertFile = v
+		}
+	}
+	if ingestTLS.KeyFile == "" {
+		if v, ok := envconfig.Lookup(signalPrefix, envconfig.ClientKey); ok {
+			ingestTLS.KeyFile = v
+		}
+	}
+
+	return &exporterOptions{
+		ingestURL:         ingestURL,
+		ingestTLSSettings: ingestTLS,
+		apiURL:            apiURL,
+		apiTLSSettings:    cfg.APITLSSettings,
+		httpTimeout:       timeout,
+		token:             token,
+		compression:       compression,
+		logDataPoints:     cfg.LogDataPoints,
+		logDimUpdate:      cfg.LogDimensionUpdates,
+	}, nil
+}
+
+// newMetricTranslator builds the metric translator for cfg, or returns nil
+// if no translation rules are configured. Unlike the other options
+// resolved by getOptionsFromConfig, this does not depend on signalPrefix,
+// so callers build it once (in newSignalFxExporter) rather than on every
+// getOptionsFromConfig call: NewMetricTranslator spawns a background
+// goroutine to purge expired delta-translation entries, which would leak
+// one instance per call otherwise.
+func newMetricTranslator(cfg *Config) (*translation.MetricTranslator, error) {
+	if len(cfg.TranslationRules) == 0 {
+		return nil, nil
+	}
+	return translation.NewMetricTranslator(cfg.TranslationRules, cfg.DeltaTranslationTTL)
+}
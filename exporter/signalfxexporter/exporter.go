@@ -15,13 +15,11 @@
 package signalfxexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter"
 
 import (
-	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
-	"sync"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -33,6 +31,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/dimensions"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/envconfig"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/hostmetadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/translation"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
@@ -69,6 +68,7 @@ type signalfxExporter struct {
 	pushLogsData       func(ctx context.Context, ld plog.Logs) (droppedLogRecords int, err error)
 	hostMetadataSyncer *hostmetadata.Syncer
 	converter          *translation.MetricsConverter
+	telemetry          *telemetry
 }
 
 type exporterOptions struct {
@@ -78,9 +78,9 @@ type exporterOptions struct {
 	apiTLSSettings    configtls.TLSClientSetting
 	httpTimeout       time.Duration
 	token             string
+	compression       string
 	logDataPoints     bool
 	logDimUpdate      bool
-	metricTranslator  *translation.MetricTranslator
 }
 
 // newSignalFxExporter returns a new SignalFx exporter.
@@ -92,15 +92,20 @@ func newSignalFxExporter(
 		return nil, errors.New("nil config")
 	}
 
-	options, err := config.getOptionsFromConfig()
+	_, err := config.getOptionsFromConfig(envconfig.MetricsPrefix)
 	if err != nil {
 		return nil, err
 	}
 
+	metricTranslator, err := newMetricTranslator(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metric translator: %w", err)
+	}
+
 	sampledLogger := translation.CreateSampledLogger(createSettings.Logger)
 	converter, err := translation.NewMetricsConverter(
 		sampledLogger,
-		options.metricTranslator,
+		metricTranslator,
 		config.ExcludeMetrics,
 		config.IncludeMetrics,
 		config.NonAlphanumericDimensionChars,
@@ -109,60 +114,87 @@ func newSignalFxExporter(
 		return nil, fmt.Errorf("failed to create metric converter: %w", err)
 	}
 
+	exporterTelemetry, err := newTelemetry(createSettings.TelemetrySettings.MeterProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exporter telemetry: %w", err)
+	}
+
 	return &signalfxExporter{
 		config:            config,
 		logger:            createSettings.Logger,
 		telemetrySettings: createSettings.TelemetrySettings,
 		converter:         converter,
+		telemetry:         exporterTelemetry,
 	}, nil
 }
 
 func (se *signalfxExporter) start(_ context.Context, host component.Host) (err error) {
-	options, err := se.config.getOptionsFromConfig()
+	options, err := se.config.getOptionsFromConfig(envconfig.MetricsPrefix)
 	if err != nil {
 		return err
 	}
+	if options.ingestURL == nil {
+		return errors.New("resolved ingest URL is nil: set ingest_url, SIGNALFX_INGEST_ENDPOINT, or realm")
+	}
 
-	headers := buildHeaders(se.config)
+	headers := buildHeaders(se.config, envconfig.MetricsPrefix)
 	client, err := se.createClient(host)
 	if err != nil {
 		return err
 	}
 
+	compressor, err := newCompressor(options.compression)
+	if err != nil {
+		return err
+	}
+
 	dpClient := &sfxDPClient{
 		sfxClientBase: sfxClientBase{
-			ingestURL: options.ingestURL,
-			headers:   headers,
-			client:    client,
-			zippers:   newGzipPool(),
+			ingestURL:               withIngestPath(options.ingestURL, datapointIngestPath),
+			headers:                 headers,
+			client:                  client,
+			compressor:              compressor,
+			compressionMinSizeBytes: defaultCompressionMinSizeBytes,
+			telemetry:               se.telemetry,
 		},
 		logDataPoints:          options.logDataPoints,
 		logger:                 se.logger,
+		rejectionLogger:        translation.CreateSampledLogger(se.logger),
 		accessTokenPassthrough: se.config.AccessTokenPassthrough,
 		converter:              se.converter,
 	}
 
+	dimOptions, err := se.config.getOptionsFromConfig(envconfig.DimensionsPrefix)
+	if err != nil {
+		return err
+	}
+	if dimOptions.apiURL == nil {
+		return errors.New("resolved API URL is nil: set api_url, SIGNALFX_API_ENDPOINT, or realm")
+	}
+
 	apiTLSCfg, err := se.config.APITLSSettings.LoadTLSConfig()
 	if err != nil {
 		return fmt.Errorf("could not load API TLS config: %w", err)
 	}
 
+	dimClientCfg := se.config.DimensionClient.withDefaults()
+
 	dimClient := dimensions.NewDimensionClient(
 		context.Background(),
 		dimensions.DimensionClientOptions{
-			Token:        options.token,
-			APIURL:       options.apiURL,
-			APITLSConfig: apiTLSCfg,
-			LogUpdates:   options.logDimUpdate,
-			Logger:       se.logger,
-			// Duration to wait between property updates. This might be worth
-			// being made configurable.
-			SendDelay: 10,
-			// In case of having issues sending dimension updates to SignalFx,
-			// buffer a fixed number of updates. Might also be a good candidate
-			// to make configurable.
-			PropertiesMaxBuffered: 10000,
+			Token:                 dimOptions.token,
+			APIURL:                dimOptions.apiURL,
+			APITLSConfig:          apiTLSCfg,
+			LogUpdates:            options.logDimUpdate,
+			Logger:                se.logger,
+			SendDelay:             dimClientCfg.SendDelay,
+			PropertiesMaxBuffered: dimClientCfg.MaxBuffered,
+			MaxRetries:            dimClientCfg.MaxRetries,
+			InitialBackoff:        dimClientCfg.InitialBackoff,
+			MaxBackoff:            dimClientCfg.MaxBackoff,
+			BackoffMultiplier:     dimClientCfg.BackoffMultiplier,
 			MetricsConverter:      *se.converter,
+			MeterProvider:         se.telemetrySettings.MeterProvider,
 		})
 	dimClient.Start()
 
@@ -176,49 +208,61 @@ func (se *signalfxExporter) start(_ context.Context, host component.Host) (err e
 	return nil
 }
 
-func newGzipPool() sync.Pool {
-	return sync.Pool{New: func() interface{} {
-		return gzip.NewWriter(nil)
-	}}
-}
-
 func newEventExporter(config *Config, createSettings exporter.CreateSettings) (*signalfxExporter, error) {
 	if config == nil {
 		return nil, errors.New("nil config")
 	}
 
-	_, err := config.getOptionsFromConfig()
+	_, err := config.getOptionsFromConfig(envconfig.LogsPrefix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process config: %w", err)
 	}
+
+	exporterTelemetry, err := newTelemetry(createSettings.TelemetrySettings.MeterProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exporter telemetry: %w", err)
+	}
+
 	return &signalfxExporter{
 		config:            config,
 		logger:            createSettings.Logger,
 		telemetrySettings: createSettings.TelemetrySettings,
+		telemetry:         exporterTelemetry,
 	}, nil
 
 }
 
 func (se *signalfxExporter) startLogs(_ context.Context, host component.Host) error {
-	options, err := se.config.getOptionsFromConfig()
+	options, err := se.config.getOptionsFromConfig(envconfig.LogsPrefix)
 	if err != nil {
 		return fmt.Errorf("failed to process config: %w", err)
 	}
+	if options.ingestURL == nil {
+		return errors.New("resolved ingest URL is nil: set ingest_url, SIGNALFX_INGEST_ENDPOINT, or realm")
+	}
 
-	headers := buildHeaders(se.config)
+	headers := buildHeaders(se.config, envconfig.LogsPrefix)
 	client, err := se.createClient(host)
 	if err != nil {
 		return err
 	}
 
+	eventCompressor, err := newCompressor(options.compression)
+	if err != nil {
+		return err
+	}
+
 	eventClient := &sfxEventClient{
 		sfxClientBase: sfxClientBase{
-			ingestURL: options.ingestURL,
-			headers:   headers,
-			client:    client,
-			zippers:   newGzipPool(),
+			ingestURL:               withIngestPath(options.ingestURL, eventIngestPath),
+			headers:                 headers,
+			client:                  client,
+			compressor:              eventCompressor,
+			compressionMinSizeBytes: defaultCompressionMinSizeBytes,
+			telemetry:               se.telemetry,
 		},
 		logger:                 se.logger,
+		rejectionLogger:        translation.CreateSampledLogger(se.logger),
 		accessTokenPassthrough: se.config.AccessTokenPassthrough,
 	}
 
@@ -256,7 +300,7 @@ func (se *signalfxExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
 	return err
 }
 
-func buildHeaders(config *Config) map[string]string {
+func buildHeaders(config *Config, signalPrefix string) map[string]string {
 	headers := map[string]string{
 		"Connection":   "keep-alive",
 		"Content-Type": "application/x-protobuf",
@@ -266,6 +310,19 @@ func buildHeaders(config *Config) map[string]string {
 	if config.AccessToken != "" {
 		headers[splunk.SFxAccessTokenHeader] = config.AccessToken
 	}
+	if config.AccessToken == "" {
+		if v, ok := envconfig.Lookup(signalPrefix, envconfig.AccessToken); ok && v != "" {
+			headers[splunk.SFxAccessTokenHeader] = v
+		}
+	}
+
+	// Headers from SIGNALFX_HEADERS (or its per-signal override) are applied
+	// before the config file's own headers, so config file values win.
+	if envHeaders, ok := envconfig.LookupHeaders(signalPrefix, envconfig.Headers); ok {
+		for k, v := range envHeaders {
+			headers[k] = v
+		}
+	}
 
 	// Add any custom headers from the config. They will override the pre-defined
 	// ones above in case of conflict, but, not the content encoding one since
@@ -0,0 +1,377 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dimensions // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/dimensions"
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/translation"
+	metadata "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
+)
+
+// DimensionClientOptions configures a DimensionClient.
+type DimensionClientOptions struct {
+	Token        string
+	APIURL       *url.URL
+	APITLSConfig *tls.Config
+	LogUpdates   bool
+	Logger       *zap.Logger
+
+	// SendDelay is how often buffered property updates are flushed.
+	SendDelay time.Duration
+	// PropertiesMaxBuffered is the maximum number of property updates
+	// buffered before new updates are dropped.
+	PropertiesMaxBuffered int
+
+	// MaxRetries is the maximum number of attempts for a single update
+	// before it is dropped.
+	MaxRetries int
+	// InitialBackoff is the backoff before the first retry of a failed
+	// update.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoff between retries, and the wait applied
+	// for a Retry-After header that requests a longer delay.
+	MaxBackoff time.Duration
+	// BackoffMultiplier scales the backoff after each failed attempt.
+	BackoffMultiplier float64
+
+	MetricsConverter translation.MetricsConverter
+
+	// MeterProvider, if non-nil, is used to report dimension update and
+	// queue-depth metrics.
+	MeterProvider metric.MeterProvider
+}
+
+// DimensionClient buffers dimension property updates and flushes them to
+// the SignalFx dimension API at a fixed interval, dropping updates once
+// PropertiesMaxBuffered is reached rather than growing without bound.
+type DimensionClient struct {
+	token        string
+	apiURL       *url.URL
+	apiTLSConfig *tls.Config
+	logUpdates   bool
+	logger       *zap.Logger
+
+	sendDelay             time.Duration
+	propertiesMaxBuffered int
+
+	maxRetries        int
+	initialBackoff    time.Duration
+	maxBackoff        time.Duration
+	backoffMultiplier float64
+
+	metricsConverter translation.MetricsConverter
+
+	client *http.Client
+
+	mu         sync.Mutex
+	pending    []*metadata.MetadataUpdate
+	authFailed bool // latched once a 401 is seen; see PushMetadata and flush
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	telemetry *telemetry
+}
+
+// NewDimensionClient returns a DimensionClient that stops flushing once ctx
+// is done.
+func NewDimensionClient(ctx context.Context, options DimensionClientOptions) *DimensionClient {
+	cctx, cancel := context.WithCancel(ctx)
+
+	dc := &DimensionClient{
+		token:                 options.Token,
+		apiURL:                options.APIURL,
+		apiTLSConfig:          options.APITLSConfig,
+		logUpdates:            options.LogUpdates,
+		logger:                options.Logger,
+		sendDelay:             options.SendDelay,
+		propertiesMaxBuffered: options.PropertiesMaxBuffered,
+		maxRetries:            options.MaxRetries,
+		initialBackoff:        options.InitialBackoff,
+		maxBackoff:            options.MaxBackoff,
+		backoffMultiplier:     options.BackoffMultiplier,
+		metricsConverter:      options.MetricsConverter,
+		client: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: options.APITLSConfig},
+		},
+		ctx:    cctx,
+		cancel: cancel,
+	}
+
+	t, err := newTelemetry(options.MeterProvider, dc.queueLength)
+	if err != nil {
+		dc.logger.Warn("failed to create dimension client telemetry, metrics will not be reported", zap.Error(err))
+	} else {
+		dc.telemetry = t
+	}
+
+	return dc
+}
+
+// Start begins the background flush loop. It must be called at most once.
+func (dc *DimensionClient) Start() {
+	dc.wg.Add(1)
+	go dc.run()
+}
+
+// Shutdown stops the flush loop and waits for it to exit.
+func (dc *DimensionClient) Shutdown() {
+	dc.cancel()
+	dc.wg.Wait()
+}
+
+func (dc *DimensionClient) run() {
+	defer dc.wg.Done()
+
+	ticker := time.NewTicker(dc.sendDelay)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-dc.ctx.Done():
+			dc.flush()
+			return
+		case <-ticker.C:
+			dc.flush()
+		}
+	}
+}
+
+// PushMetadata buffers updates for the next flush, dropping them if the
+// buffer is already at PropertiesMaxBuffered. Once a dimension update has
+// been rejected for authentication, the client latches into a permanently
+// failed state (see flush) and PushMetadata rejects every update from then
+// on, since retrying with the same token cannot succeed.
+func (dc *DimensionClient) PushMetadata(updates []*metadata.MetadataUpdate) error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if dc.authFailed {
+		return errors.New("dimension client disabled after authentication failure, dropping update(s)")
+	}
+
+	if len(dc.pending)+len(updates) > dc.propertiesMaxBuffered {
+		return fmt.Errorf("dimension update buffer full (max %d), dropping %d update(s)",
+			dc.propertiesMaxBuffered, len(updates))
+	}
+
+	dc.pending = append(dc.pending, updates...)
+	return nil
+}
+
+func (dc *DimensionClient) queueLength() int64 {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return int64(len(dc.pending))
+}
+
+// flush sends every pending update. If one is rejected for authentication,
+// the failure is latched permanently: the current batch's remainder is
+// dropped as before, but authFailed also stops every future flush and
+// PushMetadata call from doing any work, since retrying with the same token
+// would only repeat the same 401 forever.
+func (dc *DimensionClient) flush() {
+	dc.mu.Lock()
+	if dc.authFailed {
+		dc.mu.Unlock()
+		return
+	}
+	updates := dc.pending
+	dc.pending = nil
+	dc.mu.Unlock()
+
+	for i, update := range updates {
+		if !dc.sendUpdate(update) {
+			// The token was rejected: every other buffered update would
+			// fail the same way, so stop burning the retry budget on
+			// them rather than hitting the same 401 once per update, and
+			// latch so future ticks don't repeat the same failure.
+			dc.mu.Lock()
+			dc.authFailed = true
+			dc.mu.Unlock()
+			dc.logger.Error("Dimension client disabled after authentication failure, dropping remaining and future updates",
+				zap.Int("dropped", len(updates)-i))
+			return
+		}
+	}
+}
+
+// authError marks a dimension update rejected for authentication reasons
+// (401). Retrying with the same token will not help, so the caller treats
+// it as permanent rather than spinning through the retry budget.
+type authError struct{ status int }
+
+func (e *authError) Error() string {
+	return fmt.Sprintf("dimension update rejected, status %d", e.status)
+}
+
+// retryableStatusError marks a dimension update that failed with a status
+// the API expects callers to retry (429, 503), optionally after waiting
+// out a Retry-After header.
+type retryableStatusError struct {
+	status     int
+	retryAfter time.Duration
+}
+
+func (e *retryableStatusError) Error() string {
+	return fmt.Sprintf("dimension update failed, status %d", e.status)
+}
+
+// sendUpdate sends update, retrying on transient failures for at most
+// maxRetries attempts in total, and reports whether the caller's flush loop should
+// keep going. It returns false only when the token itself was rejected
+// (401): every other buffered update would fail the same way, so the
+// caller should stop rather than retry each one in turn.
+func (dc *DimensionClient) sendUpdate(update *metadata.MetadataUpdate) bool {
+	if dc.logUpdates {
+		dc.logger.Info("Sending dimension update", zap.Any("update", update))
+	}
+
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = dc.initialBackoff
+	bo.MaxInterval = dc.maxBackoff
+	bo.Multiplier = dc.backoffMultiplier
+	bo.MaxElapsedTime = 0 // bounded by maxRetries below, not elapsed wall time
+	bo.Reset()            // start from InitialInterval rather than the library default
+
+	for attempts := 1; ; attempts++ {
+		err := dc.doSendUpdate(update)
+		if err == nil {
+			if dc.telemetry != nil {
+				dc.telemetry.recordUpdate(dc.ctx, "success")
+			}
+			return true
+		}
+
+		var authErr *authError
+		if errors.As(err, &authErr) {
+			dc.logger.Error("Dimension update rejected, not retrying", zap.Error(err))
+			if dc.telemetry != nil {
+				dc.telemetry.recordUpdate(dc.ctx, "error")
+			}
+			return false
+		}
+
+		if attempts >= dc.maxRetries {
+			dc.logger.Error("Failed to send dimension update",
+				zap.Error(err), zap.Int("attempts", attempts))
+			if dc.telemetry != nil {
+				dc.telemetry.recordUpdate(dc.ctx, "error")
+			}
+			return true
+		}
+
+		// A Retry-After value is the server's own backoff, so it
+		// replaces rather than adds to our computed interval for this
+		// attempt.
+		wait := bo.NextBackOff()
+		var retryableErr *retryableStatusError
+		if errors.As(err, &retryableErr) && retryableErr.retryAfter > 0 {
+			wait = retryableErr.retryAfter
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-dc.ctx.Done():
+			if dc.telemetry != nil {
+				dc.telemetry.recordUpdate(dc.ctx, "error")
+			}
+			return true
+		}
+	}
+}
+
+func (dc *DimensionClient) doSendUpdate(update *metadata.MetadataUpdate) error {
+	body, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to encode dimension update: %w", err)
+	}
+
+	endpoint := *dc.apiURL
+	endpoint.Path = fmt.Sprintf("/v2/dimension/%s/%s", update.ResourceIDKey, update.ResourceID)
+
+	req, err := http.NewRequestWithContext(dc.ctx, http.MethodPatch, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-SF-Token", dc.token)
+
+	resp, err := dc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return &authError{status: resp.StatusCode}
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return &retryableStatusError{
+			status:     resp.StatusCode,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), dc.maxBackoff),
+		}
+	default:
+		return fmt.Errorf("dimension update failed with status %d", resp.StatusCode)
+	}
+}
+
+// parseRetryAfter parses the Retry-After header (either delay-seconds or
+// an HTTP-date, per RFC 7231), capped at maxWait so a misbehaving server
+// can't stall the flush loop indefinitely.
+func parseRetryAfter(header string, maxWait time.Duration) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(header); err == nil {
+		d := time.Duration(secs) * time.Second
+		if maxWait > 0 && d > maxWait {
+			return maxWait
+		}
+		return d
+	}
+
+	if t, err := http.ParseTime(header); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			return 0
+		}
+		if maxWait > 0 && d > maxWait {
+			return maxWait
+		}
+		return d
+	}
+
+	return 0
+}
@@ -0,0 +1,205 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dimensions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	metadata "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		maxWait time.Duration
+		want    time.Duration
+	}{
+		{
+			name: "empty header",
+			want: 0,
+		},
+		{
+			name:   "delay-seconds",
+			header: "5",
+			want:   5 * time.Second,
+		},
+		{
+			name:    "delay-seconds capped by maxWait",
+			header:  "60",
+			maxWait: 10 * time.Second,
+			want:    10 * time.Second,
+		},
+		{
+			name:   "unparseable header",
+			header: "not-a-value",
+			want:   0,
+		},
+		{
+			name:    "HTTP-date in the past clamps to zero",
+			header:  time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat),
+			maxWait: time.Minute,
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRetryAfter(tt.header, tt.maxWait)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func newTestClient(t *testing.T, apiURL *url.URL) *DimensionClient {
+	t.Helper()
+	return &DimensionClient{
+		apiURL:            apiURL,
+		token:             "token",
+		logger:            zap.NewNop(),
+		client:            http.DefaultClient,
+		maxRetries:        3,
+		initialBackoff:    time.Millisecond,
+		maxBackoff:        5 * time.Millisecond,
+		backoffMultiplier: 2,
+		ctx:               context.Background(),
+	}
+}
+
+func testUpdate() *metadata.MetadataUpdate {
+	return &metadata.MetadataUpdate{
+		ResourceIDKey: "host_id",
+		ResourceID:    metadata.ResourceID("abc123"),
+	}
+}
+
+func TestDoSendUpdateStatusMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+		wantAuth   bool
+		wantRetry  bool
+	}{
+		{name: "200 OK", statusCode: http.StatusOK},
+		{name: "401 is an auth error", statusCode: http.StatusUnauthorized, wantErr: true, wantAuth: true},
+		{name: "429 is a retryable error", statusCode: http.StatusTooManyRequests, wantErr: true, wantRetry: true},
+		{name: "503 is a retryable error", statusCode: http.StatusServiceUnavailable, wantErr: true, wantRetry: true},
+		{name: "500 is a plain error", statusCode: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			apiURL, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			dc := newTestClient(t, apiURL)
+
+			err = dc.doSendUpdate(testUpdate())
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+
+			var authErr *authError
+			assert.Equal(t, tt.wantAuth, errors.As(err, &authErr))
+			var retryErr *retryableStatusError
+			assert.Equal(t, tt.wantRetry, errors.As(err, &retryErr))
+		})
+	}
+}
+
+func TestSendUpdateRetriesThenSucceeds(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	dc := newTestClient(t, apiURL)
+
+	ok := dc.sendUpdate(testUpdate())
+	assert.True(t, ok)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestSendUpdateGivesUpAfterMaxRetries(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	dc := newTestClient(t, apiURL)
+	dc.maxRetries = 2
+
+	ok := dc.sendUpdate(testUpdate())
+	// A non-auth failure is reported true (keep going), despite being dropped.
+	assert.True(t, ok)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestFlushLatchesAfterAuthFailure(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	dc := newTestClient(t, apiURL)
+	dc.propertiesMaxBuffered = 10
+
+	require.NoError(t, dc.PushMetadata([]*metadata.MetadataUpdate{testUpdate(), testUpdate()}))
+	dc.flush()
+
+	assert.True(t, dc.authFailed)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts), "remaining batch should not be sent after the first 401")
+
+	// A later tick must not re-attempt the request at all.
+	dc.flush()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+
+	// And new updates are rejected outright rather than buffered.
+	err = dc.PushMetadata([]*metadata.MetadataUpdate{testUpdate()})
+	assert.Error(t, err)
+}
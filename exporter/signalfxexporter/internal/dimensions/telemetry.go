@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dimensions // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/dimensions"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const scopeName = "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/dimensions"
+
+// telemetry reports dimension-update outcomes and queue depth through the
+// collector's MeterProvider so operators can see why property updates are
+// failing without tcpdump.
+type telemetry struct {
+	updates metric.Int64Counter
+}
+
+// newTelemetry builds the instruments for mp, falling back to a no-op
+// provider when mp is nil. DimensionClient leaves its telemetry nil when
+// this fails, so its call sites check for nil before recording. queueLen
+// is polled by an observable gauge
+// to report how many updates are currently buffered.
+func newTelemetry(mp metric.MeterProvider, queueLen func() int64) (*telemetry, error) {
+	if mp == nil {
+		mp = noop.NewMeterProvider()
+	}
+	meter := mp.Meter(scopeName)
+
+	updates, err := meter.Int64Counter(
+		"signalfxexporter_dimension_updates",
+		metric.WithDescription("Number of dimension property updates, by result."),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	queueLength, err := meter.Int64ObservableGauge(
+		"signalfxexporter_dimension_queue_length",
+		metric.WithDescription("Number of dimension property updates buffered for sending."),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(queueLength, queueLen())
+		return nil
+	}, queueLength); err != nil {
+		return nil, err
+	}
+
+	return &telemetry{updates: updates}, nil
+}
+
+func (t *telemetry) recordUpdate(ctx context.Context, result string) {
+	t.updates.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+}
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package envconfig resolves signalfxexporter configuration values from
+// environment variables, mirroring the OTEL_EXPORTER_OTLP_* conventions
+// used by the OTLP exporters: an unprefixed variable applies to every
+// signal, and a signal-specific variable overrides it for that signal
+// only.
+package envconfig // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/internal/envconfig"
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// Signal prefixes used to look up per-signal overrides of the base
+// SIGNALFX_* variables, e.g. SIGNALFX_METRICS_INGEST_ENDPOINT overrides
+// SIGNALFX_INGEST_ENDPOINT for the metrics exporter only.
+const (
+	MetricsPrefix    = "SIGNALFX_METRICS_"
+	LogsPrefix       = "SIGNALFX_LOGS_"
+	DimensionsPrefix = "SIGNALFX_DIMENSIONS_"
+)
+
+const basePrefix = "SIGNALFX_"
+
+// Variable suffixes shared by the base and per-signal prefixes.
+const (
+	IngestEndpoint     = "INGEST_ENDPOINT"
+	APIEndpoint        = "API_ENDPOINT"
+	AccessToken        = "ACCESS_TOKEN"
+	Timeout            = "TIMEOUT"
+	Compression        = "COMPRESSION"
+	Headers            = "HEADERS"
+	Certificate        = "CERTIFICATE"
+	ClientCertificate  = "CLIENT_CERTIFICATE"
+	ClientKey          = "CLIENT_KEY"
+)
+
+// Lookup returns the value of the signal-specific variable
+// signalPrefix+suffix if set, falling back to the unprefixed
+// SIGNALFX_+suffix variable. signalPrefix may be empty, in which case only
+// the base variable is consulted.
+func Lookup(signalPrefix, suffix string) (string, bool) {
+	if signalPrefix != "" {
+		if v, ok := os.LookupEnv(signalPrefix + suffix); ok {
+			return v, true
+		}
+	}
+	return os.LookupEnv(basePrefix + suffix)
+}
+
+// LookupDuration parses the resolved variable as a Go duration string
+// (e.g. "5s").
+func LookupDuration(signalPrefix, suffix string) (time.Duration, bool, error) {
+	v, ok := Lookup(signalPrefix, suffix)
+	if !ok || v == "" {
+		return 0, false, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, true, err
+	}
+	return d, true, nil
+}
+
+// LookupHeaders parses the resolved variable as a comma-separated list of
+// key=value pairs, as used by OTEL_EXPORTER_OTLP_HEADERS.
+func LookupHeaders(signalPrefix, suffix string) (map[string]string, bool) {
+	v, ok := Lookup(signalPrefix, suffix)
+	if !ok || v == "" {
+		return nil, false
+	}
+
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, val, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(val)
+	}
+	if len(headers) == 0 {
+		return nil, false
+	}
+	return headers, true
+}
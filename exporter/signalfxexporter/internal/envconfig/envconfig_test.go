@@ -0,0 +1,186 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name         string
+		signalPrefix string
+		base         string
+		override     string
+		wantValue    string
+		wantOK       bool
+	}{
+		{
+			name:      "nothing set",
+			wantValue: "",
+			wantOK:    false,
+		},
+		{
+			name:      "base only",
+			base:      "https://base.example.com",
+			wantValue: "https://base.example.com",
+			wantOK:    true,
+		},
+		{
+			name:         "per-signal override wins over base",
+			signalPrefix: MetricsPrefix,
+			base:         "https://base.example.com",
+			override:     "https://metrics.example.com",
+			wantValue:    "https://metrics.example.com",
+			wantOK:       true,
+		},
+		{
+			name:         "empty signal prefix consults only the base variable",
+			signalPrefix: "",
+			base:         "https://base.example.com",
+			override:     "https://metrics.example.com",
+			wantValue:    "https://base.example.com",
+			wantOK:       true,
+		},
+		{
+			name:         "per-signal unset falls back to base",
+			signalPrefix: LogsPrefix,
+			base:         "https://base.example.com",
+			wantValue:    "https://base.example.com",
+			wantOK:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.base != "" {
+				t.Setenv(basePrefix+IngestEndpoint, tt.base)
+			}
+			if tt.override != "" {
+				t.Setenv(tt.signalPrefix+IngestEndpoint, tt.override)
+			}
+
+			v, ok := Lookup(tt.signalPrefix, IngestEndpoint)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantValue, v)
+		})
+	}
+}
+
+func TestLookupDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		set     bool
+		wantD   time.Duration
+		wantOK  bool
+		wantErr bool
+	}{
+		{
+			name:   "unset",
+			set:    false,
+			wantOK: false,
+		},
+		{
+			name:   "valid duration",
+			set:    true,
+			value:  "5s",
+			wantD:  5 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:    "invalid duration",
+			set:     true,
+			value:   "not-a-duration",
+			wantOK:  true,
+			wantErr: true,
+		},
+		{
+			name:   "empty value treated as unset",
+			set:    true,
+			value:  "",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.set {
+				t.Setenv(basePrefix+Timeout, tt.value)
+			}
+
+			d, ok, err := LookupDuration("", Timeout)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantD, d)
+		})
+	}
+}
+
+func TestLookupHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		wantOK bool
+		want   map[string]string
+	}{
+		{
+			name:   "unset",
+			wantOK: false,
+		},
+		{
+			name:   "single pair",
+			value:  "X-Foo=bar",
+			wantOK: true,
+			want:   map[string]string{"X-Foo": "bar"},
+		},
+		{
+			name:   "multiple pairs with surrounding whitespace",
+			value:  "X-Foo=bar, X-Baz = qux",
+			wantOK: true,
+			want:   map[string]string{"X-Foo": "bar", "X-Baz": "qux"},
+		},
+		{
+			name:   "entries without an '=' are skipped",
+			value:  "X-Foo=bar,not-a-pair,X-Baz=qux",
+			wantOK: true,
+			want:   map[string]string{"X-Foo": "bar", "X-Baz": "qux"},
+		},
+		{
+			name:   "only malformed entries yields not-ok",
+			value:  "not-a-pair, , also-not-one",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.value != "" || tt.wantOK {
+				t.Setenv(basePrefix+Headers, tt.value)
+			}
+
+			got, ok := LookupHeaders("", Headers)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// rejectedItem describes a single datapoint or event the SignalFx ingest
+// API rejected while still accepting the rest of the request.
+type rejectedItem struct {
+	Index  int    `json:"index"`
+	Reason string `json:"reason"`
+}
+
+// ingestResponse is the shape of a partial-success response body from the
+// SignalFx ingest API: a 200/207 response whose body reports that some of
+// the uploaded items were rejected (bad dimension names, metric-type
+// conflicts, invalid tokens, etc.) rather than the plain "OK" returned on
+// full success.
+type ingestResponse struct {
+	Accepted int            `json:"accepted"`
+	Rejected []rejectedItem `json:"rejected"`
+}
+
+// parseIngestResponse reads and closes resp.Body, returning the rejected
+// items reported by a partial-success response. A body that is not valid
+// JSON (e.g. the literal "OK" returned on full success) is treated as
+// "nothing rejected" rather than an error, since that is the common case.
+func parseIngestResponse(resp *http.Response) ([]rejectedItem, error) {
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var parsed ingestResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		// Not a JSON partial-success body; assume the plain "OK"/"INVALID"
+		// response and report no per-item rejections.
+		return nil, nil
+	}
+	if len(parsed.Rejected) == 0 {
+		return nil, nil
+	}
+
+	return parsed.Rejected, nil
+}
+
+// summarizeRejections builds a short human-readable summary of why items
+// were rejected, grouping by reason so that a batch of many datapoints
+// failing for the same cause doesn't produce an unreadable wall of text.
+func summarizeRejections(rejected []rejectedItem) error {
+	counts := make(map[string]int, len(rejected))
+	for _, r := range rejected {
+		reason := r.Reason
+		if reason == "" {
+			reason = "unknown reason"
+		}
+		counts[reason]++
+	}
+
+	msg := fmt.Sprintf("%d item(s) rejected", len(rejected))
+	for reason, count := range counts {
+		msg += fmt.Sprintf("; %d %s", count, reason)
+	}
+	return fmt.Errorf("%s", msg)
+}
@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIngestResponse(body string) *http.Response {
+	return &http.Response{Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestParseIngestResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []rejectedItem
+	}{
+		{
+			name: "plain OK body has no rejections",
+			body: "OK",
+			want: nil,
+		},
+		{
+			name: "empty body has no rejections",
+			body: "",
+			want: nil,
+		},
+		{
+			name: "partial success with rejections",
+			body: `{"accepted":2,"rejected":[{"index":0,"reason":"invalid metric type"}]}`,
+			want: []rejectedItem{{Index: 0, Reason: "invalid metric type"}},
+		},
+		{
+			name: "full success JSON body with no rejected items",
+			body: `{"accepted":5,"rejected":[]}`,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIngestResponse(newIngestResponse(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSummarizeRejections(t *testing.T) {
+	t.Run("single reason", func(t *testing.T) {
+		err := summarizeRejections([]rejectedItem{
+			{Index: 0, Reason: "invalid metric type"},
+			{Index: 1, Reason: "invalid metric type"},
+		})
+		require.Error(t, err)
+		assert.Equal(t, "2 item(s) rejected; 2 invalid metric type", err.Error())
+	})
+
+	t.Run("missing reason defaults to unknown", func(t *testing.T) {
+		err := summarizeRejections([]rejectedItem{{Index: 0, Reason: ""}})
+		require.Error(t, err)
+		assert.Equal(t, "1 item(s) rejected; 1 unknown reason", err.Error())
+	})
+
+	t.Run("groups multiple reasons", func(t *testing.T) {
+		err := summarizeRejections([]rejectedItem{
+			{Index: 0, Reason: "invalid metric type"},
+			{Index: 1, Reason: "bad dimension"},
+		})
+		require.Error(t, err)
+		msg := err.Error()
+		assert.True(t, strings.HasPrefix(msg, "2 item(s) rejected"))
+		assert.Contains(t, msg, "1 invalid metric type")
+		assert.Contains(t, msg, "1 bad dimension")
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const scopeName = "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter"
+
+// telemetry holds the self-observability instruments reported through the
+// collector's MeterProvider, giving operators visibility into why
+// datapoints or events are being dropped without resorting to tcpdump.
+type telemetry struct {
+	datapointsSent      metric.Int64Counter
+	datapointsDropped   metric.Int64Counter
+	eventsSent          metric.Int64Counter
+	httpRequestDuration metric.Float64Histogram
+	compressionRatio    metric.Float64Histogram
+}
+
+// newTelemetry builds the instruments for mp, falling back to a no-op
+// provider when mp is nil. A client built without telemetry holds a nil
+// *telemetry, so the clients check for nil before recording.
+func newTelemetry(mp metric.MeterProvider) (*telemetry, error) {
+	if mp == nil {
+		mp = noop.NewMeterProvider()
+	}
+	meter := mp.Meter(scopeName)
+
+	var err error
+	t := &telemetry{}
+
+	if t.datapointsSent, err = meter.Int64Counter(
+		"signalfxexporter_datapoints_sent",
+		metric.WithDescription("Number of datapoints successfully accepted by the SignalFx ingest API."),
+		metric.WithUnit("1"),
+	); err != nil {
+		return nil, err
+	}
+	if t.datapointsDropped, err = meter.Int64Counter(
+		"signalfxexporter_datapoints_dropped",
+		metric.WithDescription("Number of datapoints dropped, by reason."),
+		metric.WithUnit("1"),
+	); err != nil {
+		return nil, err
+	}
+	if t.eventsSent, err = meter.Int64Counter(
+		"signalfxexporter_events_sent",
+		metric.WithDescription("Number of events successfully accepted by the SignalFx ingest API."),
+		metric.WithUnit("1"),
+	); err != nil {
+		return nil, err
+	}
+	if t.httpRequestDuration, err = meter.Float64Histogram(
+		"signalfxexporter_http_request_duration_seconds",
+		metric.WithDescription("Duration of HTTP requests to the SignalFx API, by endpoint."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	if t.compressionRatio, err = meter.Float64Histogram(
+		"signalfxexporter_compression_ratio",
+		metric.WithDescription("Ratio of uncompressed to compressed payload size."),
+		metric.WithUnit("1"),
+	); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *telemetry) recordDropped(ctx context.Context, count int64, reason string) {
+	if t == nil || count == 0 {
+		return
+	}
+	t.datapointsDropped.Add(ctx, count, metric.WithAttributes(attribute.String("reason", reason)))
+}